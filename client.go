@@ -26,9 +26,12 @@ func (b basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error)
 }
 
 type RpcClient struct {
-	rpc        *xmlrpc.Client
-	configFile string
-	httpServer struct {
+	rpc           *xmlrpc.Client
+	configFile    string
+	transport     string
+	remoteLogTail bool
+	retry         retryPolicy
+	httpServer    struct {
 		url      string
 		username string
 		password string
@@ -66,6 +69,27 @@ func WithHttpServer(url, username, password string) Option {
 	}
 }
 
+// WithRemoteLogTail forces Process.StdoutLog/StderrLog to stream log data
+// through supervisord's RPCs instead of opening the log file on the local
+// filesystem, even when the client is otherwise connected via WithUnixSock.
+// Useful when the unix socket is reachable (e.g. through an SSH tunnel) but
+// the log files themselves live on a different host. Pass it to NewRpcClient
+// alongside the transport option, e.g.
+// NewRpcClient(WithUnixSock(path), WithRemoteLogTail()).
+func WithRemoteLogTail() Option {
+	return func(c *RpcClient) {
+		c.remoteLogTail = true
+	}
+}
+
+// transport identifies which socket kind a RpcClient ended up connecting
+// through, since a single RpcClient may be configured with both options and
+// fall back from one to the other.
+const (
+	transportUnixSock = "unix"
+	transportHttp     = "http"
+)
+
 func (r *RpcClient) initHttpRpcClient() error {
 	var err error
 	tr := http.DefaultTransport
@@ -73,6 +97,9 @@ func (r *RpcClient) initHttpRpcClient() error {
 		tr = &basicAuthTransport{username: r.httpServer.username, password: r.httpServer.password, rt: tr}
 	}
 	r.rpc, err = xmlrpc.NewClient(fmt.Sprintf("http://%s/RPC2", r.httpServer.url), tr)
+	if err == nil {
+		r.transport = transportHttp
+	}
 	return err
 }
 
@@ -86,6 +113,9 @@ func (r *RpcClient) initUnixSockRpcClient() error {
 	var err error
 	// ignore this rpc address, only for url.Parse() and /RPC2 context
 	r.rpc, err = xmlrpc.NewClient("http://127.0.0.1/RPC2", tr)
+	if err == nil {
+		r.transport = transportUnixSock
+	}
 	return err
 }
 
@@ -97,7 +127,7 @@ func (r *RpcClient) initRpcClient() error {
 		}
 		r.httpServer.url = cfg.InetHttpServer.ServerUrl
 		r.httpServer.username = cfg.InetHttpServer.Username
-		r.httpServer.password = cfg.InetHttpServer.Username
+		r.httpServer.password = cfg.InetHttpServer.Password
 		r.unixSock.path = strings.TrimPrefix(cfg.UnixSock.SockPath, "unix://")
 	}
 	if r.unixSock.path != "" {
@@ -113,12 +143,20 @@ func (r *RpcClient) initRpcClient() error {
 	return errors.New("init rpc client error: inet_http_server is disabled or find unix sock path failed")
 }
 
+// useRemoteLogTail reports whether Process.StdoutLog/StderrLog should stream
+// through supervisord's RPCs rather than opening a local log file.
+func (r *RpcClient) useRemoteLogTail() bool {
+	return r.remoteLogTail || r.transport == transportHttp
+}
+
 func (r *RpcClient) Close() error {
 	return r.rpc.Close()
 }
 
-func NewRpcClient(option Option) (*RpcClient, error) {
+func NewRpcClient(options ...Option) (*RpcClient, error) {
 	r := &RpcClient{}
-	option(r)
+	for _, option := range options {
+		option(r)
+	}
 	return r, r.initRpcClient()
 }
@@ -1,6 +1,11 @@
 package supervisord
 
 import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
 	"github.com/go-ini/ini"
 )
 
@@ -23,3 +28,289 @@ func ParseRpcConfig(configFile string) (*RpcConfig, error) {
 	var cfg RpcConfig
 	return &cfg, ini.MapTo(&cfg, configFile)
 }
+
+// UnixHttpServerSection is the `[unix_http_server]` section: the socket
+// supervisord itself listens on.
+type UnixHttpServerSection struct {
+	File     string `ini:"file"`
+	Username string `ini:"username"`
+	Password string `ini:"password"`
+}
+
+// InetHttpServerSection is the `[inet_http_server]` section: the TCP address
+// supervisord itself listens on.
+type InetHttpServerSection struct {
+	Port     string `ini:"port"`
+	Username string `ini:"username"`
+	Password string `ini:"password"`
+}
+
+// SupervisordSection is the `[supervisord]` section: the daemon's own
+// runtime options.
+type SupervisordSection struct {
+	LogFile         string `ini:"logfile,omitempty"`
+	LogFileMaxBytes string `ini:"logfile_maxbytes,omitempty"`
+	LogFileBackups  int    `ini:"logfile_backups,omitempty"`
+	LogLevel        string `ini:"loglevel,omitempty"`
+	Pidfile         string `ini:"pidfile,omitempty"`
+	Nodaemon        bool   `ini:"nodaemon,omitempty"`
+	Silent          bool   `ini:"silent,omitempty"`
+	MinFds          int    `ini:"minfds,omitempty"`
+	MinProcs        int    `ini:"minprocs,omitempty"`
+	Umask           string `ini:"umask,omitempty"`
+	Directory       string `ini:"directory,omitempty"`
+	Environment     string `ini:"environment,omitempty"`
+}
+
+// SupervisorctlSection is the `[supervisorctl]` section: how a client should
+// connect to supervisord.
+type SupervisorctlSection struct {
+	ServerUrl string `ini:"serverurl"`
+	Username  string `ini:"username,omitempty"`
+	Password  string `ini:"password,omitempty"`
+}
+
+// IncludeSection is the `[include]` section: glob patterns for additional
+// config files, resolved relative to the file that declares them.
+type IncludeSection struct {
+	Files string `ini:"files"`
+}
+
+// ProgramConfig is a `[program:x]` section.
+type ProgramConfig struct {
+	Name                  string `ini:"-"`
+	Command               string `ini:"command"`
+	ProcessName           string `ini:"process_name,omitempty"`
+	NumProcs              int    `ini:"numprocs,omitempty"`
+	Directory             string `ini:"directory,omitempty"`
+	Umask                 string `ini:"umask,omitempty"`
+	Priority              int    `ini:"priority,omitempty"`
+	Autostart             bool   `ini:"autostart,omitempty"`
+	AutoRestart           string `ini:"autorestart,omitempty"`
+	StartSecs             int    `ini:"startsecs,omitempty"`
+	StartRetries          int    `ini:"startretries,omitempty"`
+	ExitCodes             string `ini:"exitcodes,omitempty"`
+	StopSignal            string `ini:"stopsignal,omitempty"`
+	StopWaitSecs          int    `ini:"stopwaitsecs,omitempty"`
+	StopAsGroup           bool   `ini:"stopasgroup,omitempty"`
+	KillAsGroup           bool   `ini:"killasgroup,omitempty"`
+	User                  string `ini:"user,omitempty"`
+	RedirectStderr        bool   `ini:"redirect_stderr,omitempty"`
+	StdoutLogfile         string `ini:"stdout_logfile,omitempty"`
+	StdoutLogfileMaxBytes string `ini:"stdout_logfile_maxbytes,omitempty"`
+	StdoutLogfileBackups  int    `ini:"stdout_logfile_backups,omitempty"`
+	StderrLogfile         string `ini:"stderr_logfile,omitempty"`
+	StderrLogfileMaxBytes string `ini:"stderr_logfile_maxbytes,omitempty"`
+	StderrLogfileBackups  int    `ini:"stderr_logfile_backups,omitempty"`
+	Environment           string `ini:"environment,omitempty"`
+}
+
+// GroupConfig is a `[group:x]` section.
+type GroupConfig struct {
+	Name     string `ini:"-"`
+	Programs string `ini:"programs"`
+	Priority int    `ini:"priority,omitempty"`
+}
+
+// EventListenerConfig is an `[eventlistener:x]` section.
+type EventListenerConfig struct {
+	Name                  string `ini:"-"`
+	Command               string `ini:"command"`
+	Events                string `ini:"events"`
+	NumProcs              int    `ini:"numprocs,omitempty"`
+	BufferSize            int    `ini:"buffer_size,omitempty"`
+	Priority              int    `ini:"priority,omitempty"`
+	Autostart             bool   `ini:"autostart,omitempty"`
+	AutoRestart           string `ini:"autorestart,omitempty"`
+	StartSecs             int    `ini:"startsecs,omitempty"`
+	StartRetries          int    `ini:"startretries,omitempty"`
+	StopSignal            string `ini:"stopsignal,omitempty"`
+	StopWaitSecs          int    `ini:"stopwaitsecs,omitempty"`
+	User                  string `ini:"user,omitempty"`
+	RedirectStderr        bool   `ini:"redirect_stderr,omitempty"`
+	StdoutLogfile         string `ini:"stdout_logfile,omitempty"`
+	StdoutLogfileMaxBytes string `ini:"stdout_logfile_maxbytes,omitempty"`
+	StdoutLogfileBackups  int    `ini:"stdout_logfile_backups,omitempty"`
+	StderrLogfile         string `ini:"stderr_logfile,omitempty"`
+	StderrLogfileMaxBytes string `ini:"stderr_logfile_maxbytes,omitempty"`
+	StderrLogfileBackups  int    `ini:"stderr_logfile_backups,omitempty"`
+	Environment           string `ini:"environment,omitempty"`
+}
+
+// SupervisorConfig is a fully parsed supervisord config file, including
+// every program/group/eventlistener section and any files pulled in through
+// `[include]`.
+type SupervisorConfig struct {
+	UnixHttpServer UnixHttpServerSection
+	InetHttpServer InetHttpServerSection
+	Supervisord    SupervisordSection
+	Supervisorctl  SupervisorctlSection
+	Include        IncludeSection
+	Programs       map[string]ProgramConfig
+	Groups         map[string]GroupConfig
+	EventListeners map[string]EventListenerConfig
+}
+
+// LoadConfig parses a supervisord config file, following any `[include]`
+// glob patterns (resolved relative to the including file) into the same
+// SupervisorConfig.
+func LoadConfig(path string) (*SupervisorConfig, error) {
+	cfg := &SupervisorConfig{
+		Programs:       make(map[string]ProgramConfig),
+		Groups:         make(map[string]GroupConfig),
+		EventListeners: make(map[string]EventListenerConfig),
+	}
+	if err := cfg.load(path); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *SupervisorConfig) load(path string) error {
+	f, err := ini.LoadSources(ini.LoadOptions{AllowPythonMultilineValues: true}, path)
+	if err != nil {
+		return err
+	}
+	for _, sec := range f.Sections() {
+		name := sec.Name()
+		switch {
+		case name == ini.DefaultSection:
+			continue
+		case name == "unix_http_server":
+			err = sec.MapTo(&c.UnixHttpServer)
+		case name == "inet_http_server":
+			err = sec.MapTo(&c.InetHttpServer)
+		case name == "supervisord":
+			err = sec.MapTo(&c.Supervisord)
+		case name == "supervisorctl":
+			err = sec.MapTo(&c.Supervisorctl)
+		case name == "include":
+			err = sec.MapTo(&c.Include)
+		case strings.HasPrefix(name, "program:"):
+			var p ProgramConfig
+			if err = sec.MapTo(&p); err == nil {
+				p.Name = strings.TrimPrefix(name, "program:")
+				c.Programs[p.Name] = p
+			}
+		case strings.HasPrefix(name, "group:"):
+			var g GroupConfig
+			if err = sec.MapTo(&g); err == nil {
+				g.Name = strings.TrimPrefix(name, "group:")
+				c.Groups[g.Name] = g
+			}
+		case strings.HasPrefix(name, "eventlistener:"):
+			var e EventListenerConfig
+			if err = sec.MapTo(&e); err == nil {
+				e.Name = strings.TrimPrefix(name, "eventlistener:")
+				c.EventListeners[e.Name] = e
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return c.loadIncludes(path)
+}
+
+func (c *SupervisorConfig) loadIncludes(path string) error {
+	if c.Include.Files == "" {
+		return nil
+	}
+	include := c.Include
+	base := filepath.Dir(path)
+	for _, pattern := range strings.Fields(include.Files) {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(base, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		for _, m := range matches {
+			// an included file only follows its own [include] section
+			c.Include = IncludeSection{}
+			if err := c.load(m); err != nil {
+				return err
+			}
+		}
+	}
+	c.Include = include
+	return nil
+}
+
+// WriteTo renders cfg as a valid supervisord ini file. Top-level sections
+// (unix_http_server, inet_http_server, supervisord, supervisorctl) that were
+// never populated are omitted rather than written out empty, so writing a
+// partial SupervisorConfig (e.g. just Programs, as CreateProgram does for a
+// drop-in file) doesn't emit bogus sections like an inet_http_server with no
+// port.
+func (c *SupervisorConfig) WriteTo(w io.Writer) (int64, error) {
+	f := ini.Empty()
+	sections := []struct {
+		name string
+		val  interface{}
+	}{
+		{"unix_http_server", &c.UnixHttpServer},
+		{"inet_http_server", &c.InetHttpServer},
+		{"supervisord", &c.Supervisord},
+		{"supervisorctl", &c.Supervisorctl},
+	}
+	for _, s := range sections {
+		if isZeroSection(s.val) {
+			continue
+		}
+		sec, err := f.NewSection(s.name)
+		if err != nil {
+			return 0, err
+		}
+		if err := sec.ReflectFrom(s.val); err != nil {
+			return 0, err
+		}
+	}
+	for name, p := range c.Programs {
+		sec, err := f.NewSection("program:" + name)
+		if err != nil {
+			return 0, err
+		}
+		p := p
+		if err := sec.ReflectFrom(&p); err != nil {
+			return 0, err
+		}
+	}
+	for name, g := range c.Groups {
+		sec, err := f.NewSection("group:" + name)
+		if err != nil {
+			return 0, err
+		}
+		g := g
+		if err := sec.ReflectFrom(&g); err != nil {
+			return 0, err
+		}
+	}
+	for name, e := range c.EventListeners {
+		sec, err := f.NewSection("eventlistener:" + name)
+		if err != nil {
+			return 0, err
+		}
+		e := e
+		if err := sec.ReflectFrom(&e); err != nil {
+			return 0, err
+		}
+	}
+	if c.Include.Files != "" {
+		sec, err := f.NewSection("include")
+		if err != nil {
+			return 0, err
+		}
+		if err := sec.ReflectFrom(&c.Include); err != nil {
+			return 0, err
+		}
+	}
+	return f.WriteTo(w)
+}
+
+// isZeroSection reports whether v (a pointer to one of the section structs)
+// is still its zero value, i.e. was never populated.
+func isZeroSection(v interface{}) bool {
+	return reflect.ValueOf(v).Elem().IsZero()
+}
@@ -11,25 +11,25 @@ type DaemonClient struct {
 
 func (d DaemonClient) APIVersion() (string, error) {
 	var ret string
-	return ret, d.rpc.Call("supervisor.getAPIVersion", nil, &ret)
+	return ret, d.call("supervisor.getAPIVersion", nil, &ret)
 }
 
 func (d DaemonClient) SupervisordVersion() (string, error) {
 	var ret string
-	return ret, d.rpc.Call("supervisor.getSupervisorVersion", nil, &ret)
+	return ret, d.call("supervisor.getSupervisorVersion", nil, &ret)
 }
 
 func (d DaemonClient) State() (*DaemonState, error) {
 	var ret DaemonState
-	return &ret, d.rpc.Call("supervisor.getState", nil, &ret)
+	return &ret, d.call("supervisor.getState", nil, &ret)
 }
 
-func (d DaemonClient) Shutdown() error {
-	return d.rpc.Call("supervisor.shutdown", nil, nil)
+func (d DaemonClient) Shutdown(opts ...CallOption) error {
+	return d.call("supervisor.shutdown", nil, nil, opts...)
 }
 
-func (d DaemonClient) Restart() error {
-	return d.rpc.Call("supervisor.restart", nil, nil)
+func (d DaemonClient) Restart(opts ...CallOption) error {
+	return d.call("supervisor.restart", nil, nil, opts...)
 }
 
 func NewDaemonControl(client *RpcClient) *DaemonClient {
@@ -0,0 +1,243 @@
+// Package eventlistener implements the supervisord event listener protocol,
+// so Go programs can be registered as an `[eventlistener:x]` the same way
+// Python programs use supervisor.childutils.
+//
+// See http://supervisord.org/events.html for the wire format.
+package eventlistener
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Header is the `key:value` preamble supervisord sends before every event
+// payload.
+type Header struct {
+	Ver        string
+	Server     string
+	Serial     string
+	Pool       string
+	PoolSerial string
+	EventName  string
+	Len        int
+}
+
+// Event is implemented by every typed event this package knows how to parse.
+type Event interface {
+	// Name returns the raw supervisord event name, e.g. "PROCESS_STATE_EXITED".
+	Name() string
+}
+
+// ProcessStateEvent covers the `PROCESS_STATE_*` event family.
+type ProcessStateEvent struct {
+	EventName   string
+	ProcessName string
+	GroupName   string
+	FromState   string
+	Expected    bool
+	Pid         int
+}
+
+func (e ProcessStateEvent) Name() string { return e.EventName }
+
+// TickEvent covers the `TICK_5`, `TICK_60` and `TICK_3600` events supervisord
+// emits on a timer.
+type TickEvent struct {
+	EventName string
+	When      int
+}
+
+func (e TickEvent) Name() string { return e.EventName }
+
+// ProcessLogEvent covers `PROCESS_LOG_STDOUT`/`PROCESS_LOG_STDERR`.
+type ProcessLogEvent struct {
+	EventName   string
+	ProcessName string
+	GroupName   string
+	Pid         int
+	Data        string
+}
+
+func (e ProcessLogEvent) Name() string { return e.EventName }
+
+// UnknownEvent is returned for event names this package doesn't parse a
+// dedicated payload for; Fields holds the raw header:value pairs.
+type UnknownEvent struct {
+	EventName string
+	Fields    map[string]string
+	Data      string
+}
+
+func (e UnknownEvent) Name() string { return e.EventName }
+
+// HandlerFunc handles a single dispatched event. Returning a non-nil error
+// causes the listener to ACK the event with RESULT FAIL instead of OK.
+type HandlerFunc func(ctx context.Context, event Event) error
+
+// EventListener speaks the supervisord event listener protocol on the given
+// reader/writer, which in a real `[eventlistener:x]` program are the
+// process's stdin and stdout.
+type EventListener struct {
+	in       *bufio.Reader
+	out      io.Writer
+	handlers map[string]HandlerFunc
+}
+
+// New returns an EventListener that reads events from in and writes its
+// responses to out. A program spawned by supervisord as an event listener
+// passes os.Stdin and os.Stdout.
+func New(in io.Reader, out io.Writer) *EventListener {
+	return &EventListener{in: bufio.NewReader(in), out: out, handlers: make(map[string]HandlerFunc)}
+}
+
+// Handle registers h to run whenever an event named eventName is received.
+// Use "*" to handle every event not otherwise registered.
+func (l *EventListener) Handle(eventName string, h HandlerFunc) {
+	l.handlers[eventName] = h
+}
+
+// Listen blocks, reading and dispatching events until ctx is cancelled or a
+// protocol error occurs on the transport. ctx is checked before each event is
+// requested; a read already waiting on the transport is not interrupted.
+func (l *EventListener) Listen(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if err := l.ready(); err != nil {
+			return err
+		}
+		header, rawPayload, err := l.readEvent()
+		if err != nil {
+			return err
+		}
+		event := parseEvent(header, rawPayload)
+		handler, ok := l.handlers[header.EventName]
+		if !ok {
+			handler, ok = l.handlers["*"]
+		}
+		if !ok {
+			if err := l.ack(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := handler(ctx, event); err != nil {
+			if ackErr := l.fail(); ackErr != nil {
+				return ackErr
+			}
+			continue
+		}
+		if err := l.ack(); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *EventListener) ready() error {
+	_, err := io.WriteString(l.out, "READY\n")
+	return err
+}
+
+func (l *EventListener) ack() error {
+	_, err := io.WriteString(l.out, "RESULT 2\nOK")
+	return err
+}
+
+func (l *EventListener) fail() error {
+	_, err := io.WriteString(l.out, "RESULT 4\nFAIL")
+	return err
+}
+
+func (l *EventListener) readEvent() (Header, string, error) {
+	line, err := l.in.ReadString('\n')
+	if err != nil {
+		return Header{}, "", err
+	}
+	fields := parsePairs(line)
+	length, _ := strconv.Atoi(fields["len"])
+	header := Header{
+		Ver:        fields["ver"],
+		Server:     fields["server"],
+		Serial:     fields["serial"],
+		Pool:       fields["pool"],
+		PoolSerial: fields["poolserial"],
+		EventName:  fields["eventname"],
+		Len:        length,
+	}
+	payload := make([]byte, header.Len)
+	if _, err := io.ReadFull(l.in, payload); err != nil {
+		return header, "", err
+	}
+	return header, string(payload), nil
+}
+
+// parsePairs splits a supervisord header/payload line into its `key:value`
+// fields. The wire format does not URL-encode these values, so they are
+// taken verbatim.
+func parsePairs(s string) map[string]string {
+	fields := make(map[string]string)
+	for _, part := range strings.Fields(s) {
+		k, v, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		fields[k] = v
+	}
+	return fields
+}
+
+// splitPayload separates the `key:value` preamble of a payload from any raw
+// data that follows it (only log/communication events carry trailing data).
+func splitPayload(raw string) (map[string]string, string) {
+	head, data, hasData := strings.Cut(raw, "\n")
+	fields := parsePairs(head)
+	if !hasData {
+		return fields, ""
+	}
+	return fields, data
+}
+
+func parseEvent(header Header, raw string) Event {
+	switch {
+	case strings.HasPrefix(header.EventName, "PROCESS_STATE"):
+		fields, _ := splitPayload(raw)
+		pid, _ := strconv.Atoi(fields["pid"])
+		return ProcessStateEvent{
+			EventName:   header.EventName,
+			ProcessName: fields["processname"],
+			GroupName:   fields["groupname"],
+			FromState:   fields["from_state"],
+			Expected:    fields["expected"] == "1",
+			Pid:         pid,
+		}
+	case strings.HasPrefix(header.EventName, "TICK_"):
+		fields, _ := splitPayload(raw)
+		when, _ := strconv.Atoi(fields["when"])
+		return TickEvent{EventName: header.EventName, When: when}
+	case strings.HasPrefix(header.EventName, "PROCESS_LOG"):
+		fields, data := splitPayload(raw)
+		pid, _ := strconv.Atoi(fields["pid"])
+		return ProcessLogEvent{
+			EventName:   header.EventName,
+			ProcessName: fields["processname"],
+			GroupName:   fields["groupname"],
+			Pid:         pid,
+			Data:        data,
+		}
+	default:
+		fields, data := splitPayload(raw)
+		return UnknownEvent{EventName: header.EventName, Fields: fields, Data: data}
+	}
+}
+
+// String implements fmt.Stringer for debugging/logging.
+func (h Header) String() string {
+	return fmt.Sprintf("%s serial=%s pool=%s/%s", h.EventName, h.Serial, h.Pool, h.PoolSerial)
+}
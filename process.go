@@ -3,11 +3,15 @@ package supervisord
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/Riete/supervisord/eventlistener"
 	"github.com/go-ini/ini"
 )
 
@@ -59,51 +63,66 @@ func (s StartStopAllRet) IsAllSuccess() bool {
 
 type Process struct {
 	*RpcClient
+	eventListener *eventlistener.EventListener
 }
 
-func (p Process) Start(name string) error {
+// ProcessOption configures a Process at construction time.
+type ProcessOption func(*Process)
+
+// WithEventListener attaches an already-constructed EventListener to the
+// Process. When set, Watch consumes PROCESS_STATE_* events from l (which the
+// caller is expected to be running via l.Listen elsewhere, typically because
+// this program is itself registered as supervisord's `[eventlistener:x]`)
+// instead of polling getAllProcessInfo.
+func WithEventListener(l *eventlistener.EventListener) ProcessOption {
+	return func(p *Process) {
+		p.eventListener = l
+	}
+}
+
+func (p Process) Start(name string, opts ...CallOption) error {
 	status, err := p.Status(name)
 	if err != nil {
 		return err
 	}
 	if status != Running && status != Starting {
-		return p.rpc.Call("supervisor.startProcess", name, nil)
+		return p.call("supervisor.startProcess", name, nil, opts...)
 	}
 	return nil
 }
 
-func (p Process) StartAll() (StartStopAllRet, bool, error) {
+func (p Process) StartAll(opts ...CallOption) (StartStopAllRet, bool, error) {
 	var ret StartStopAllRet
-	if err := p.rpc.Call("supervisor.startAllProcesses", nil, &ret); err != nil {
+	if err := p.call("supervisor.startAllProcesses", nil, &ret, opts...); err != nil {
 		return ret, false, err
 	}
 	return ret, ret.IsAllSuccess(), nil
 }
 
-func (p Process) Stop(name string) error {
+func (p Process) Stop(name string, opts ...CallOption) error {
 	status, err := p.Status(name)
 	if err != nil {
 		return err
 	}
 	if status == Running || status == Starting {
-		return p.rpc.Call("supervisor.stopProcess", name, nil)
+		return p.call("supervisor.stopProcess", name, nil, opts...)
 	}
 	return nil
 }
 
-func (p Process) StopAll() (StartStopAllRet, bool, error) {
+func (p Process) StopAll(opts ...CallOption) (StartStopAllRet, bool, error) {
 	var ret StartStopAllRet
-	if err := p.rpc.Call("supervisor.stopAllProcesses", nil, &ret); err != nil {
+	if err := p.call("supervisor.stopAllProcesses", nil, &ret, opts...); err != nil {
 		return ret, false, err
 	}
 	return ret, ret.IsAllSuccess(), nil
 }
 
-func (p Process) Restart(name string) error {
-	if err := p.Stop(name); err != nil {
+func (p Process) Restart(name string, opts ...CallOption) error {
+	if err := p.Stop(name, opts...); err != nil {
 		return err
 	}
-	return p.Start(name)
+	return p.Start(name, opts...)
 }
 
 func (p Process) Status(name string) (string, error) {
@@ -116,32 +135,32 @@ func (p Process) Status(name string) (string, error) {
 
 func (p Process) Info(name string) (*ProcessInfo, error) {
 	var ret ProcessInfo
-	return &ret, p.rpc.Call("supervisor.getProcessInfo", name, &ret)
+	return &ret, p.call("supervisor.getProcessInfo", name, &ret)
 }
 
 func (p Process) AllInfo() ([]ProcessInfo, error) {
 	var ret []ProcessInfo
-	return ret, p.rpc.Call("supervisor.getAllProcessInfo", nil, &ret)
+	return ret, p.call("supervisor.getAllProcessInfo", nil, &ret)
 }
 
 // Reread return [added] [changed] [removed]
 func (p Process) Reread() ([]string, []string, []string, error) {
 	var ret [][][]string
-	if err := p.rpc.Call("supervisor.reloadConfig", nil, &ret); err != nil {
+	if err := p.call("supervisor.reloadConfig", nil, &ret); err != nil {
 		return nil, nil, nil, err
 	}
 	return ret[0][0], ret[0][1], ret[0][2], nil
 }
 
-func (p Process) Add(name string) error {
-	return p.rpc.Call("supervisor.addProcessGroup", name, nil)
+func (p Process) Add(name string, opts ...CallOption) error {
+	return p.call("supervisor.addProcessGroup", name, nil, opts...)
 }
 
-func (p Process) Remove(name string) error {
-	if err := p.Stop(name); err != nil {
+func (p Process) Remove(name string, opts ...CallOption) error {
+	if err := p.Stop(name, opts...); err != nil {
 		return err
 	}
-	return p.rpc.Call("supervisor.removeProcessGroup", name, nil)
+	return p.call("supervisor.removeProcessGroup", name, nil, opts...)
 }
 
 func (p Process) Update() (map[string][]string, error) {
@@ -181,6 +200,50 @@ func (p Process) Options(name, configFile string) (map[string]string, error) {
 	return m, nil
 }
 
+// CreateProgram writes cfg as a `[program:x]` drop-in file into the config
+// file's `[include]` directory and calls Update() to load it, closing the
+// gap where Options() can read a program section but nothing could generate
+// one. It requires the RpcClient to have been built with WithConfigFile (or
+// WithDefaultConfigFile) so the include directory can be located.
+func (p Process) CreateProgram(cfg ProgramConfig) (map[string][]string, error) {
+	if p.configFile == "" {
+		return nil, errors.New("create program requires a config file to locate the include directory")
+	}
+	dir, err := p.includeDir()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(dir, cfg.Name+".conf"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := SupervisorConfig{Programs: map[string]ProgramConfig{cfg.Name: cfg}}
+	if _, err := out.WriteTo(f); err != nil {
+		return nil, err
+	}
+	return p.Update()
+}
+
+// includeDir resolves the directory supervisord drop-in program files
+// should be written to, based on the first pattern in the config's
+// `[include]` section.
+func (p Process) includeDir() (string, error) {
+	cfg, err := LoadConfig(p.configFile)
+	if err != nil {
+		return "", err
+	}
+	patterns := strings.Fields(cfg.Include.Files)
+	if len(patterns) == 0 {
+		return "", errors.New("no [include] files pattern configured")
+	}
+	dir := filepath.Dir(patterns[0])
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(p.configFile), dir)
+	}
+	return dir, nil
+}
+
 func (p Process) logTail(ctx context.Context, r io.ReadCloser, ch chan<- string) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -229,6 +292,11 @@ func (p Process) openLogFile(path string, offset int64) (io.ReadCloser, error) {
 }
 
 func (p Process) StdoutLog(ctx context.Context, name string, offset int64) (<-chan string, error) {
+	if p.useRemoteLogTail() {
+		ch := make(chan string)
+		go p.rpcLogTail(ctx, "supervisor.tailProcessStdoutLog", name, offset, ch)
+		return ch, nil
+	}
 	info, err := p.Info(name)
 	if err != nil {
 		return nil, err
@@ -243,6 +311,11 @@ func (p Process) StdoutLog(ctx context.Context, name string, offset int64) (<-ch
 }
 
 func (p Process) StderrLog(ctx context.Context, name string, offset int64) (<-chan string, error) {
+	if p.useRemoteLogTail() {
+		ch := make(chan string)
+		go p.rpcLogTail(ctx, "supervisor.tailProcessStderrLog", name, offset, ch)
+		return ch, nil
+	}
 	info, err := p.Info(name)
 	if err != nil {
 		return nil, err
@@ -260,6 +333,96 @@ func (p Process) StderrLog(ctx context.Context, name string, offset int64) (<-ch
 	return ch, nil
 }
 
-func NewProcessControl(client *RpcClient) *Process {
-	return &Process{client}
+// tailChunkSize is how many bytes are requested from supervisord per
+// tailProcessStdoutLog/tailProcessStderrLog poll.
+const tailChunkSize = 4096
+
+// rpcLogTail streams a process's log over XML-RPC instead of a local file,
+// for clients connected to a remote supervisord (see WithRemoteLogTail). It
+// polls method with the last offset tailProcessStd{out,err}Log returned,
+// mirroring logTail's local-file behavior on the same <-chan string contract.
+func (p Process) rpcLogTail(ctx context.Context, method string, name string, offset int64, ch chan<- string) {
+	defer func() {
+		if err := recover(); err != nil {
+			ch <- fmt.Sprintf("%v", err)
+		}
+		close(ch)
+	}()
+	offset, err := p.resolveTailOffset(method, name, offset)
+	if err != nil {
+		ch <- err.Error()
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		var ret []interface{}
+		if err := p.call(method, []interface{}{name, offset, tailChunkSize}, &ret); err != nil {
+			ch <- err.Error()
+			return
+		}
+		if len(ret) != 3 {
+			ch <- "unexpected tailProcessLog response shape"
+			return
+		}
+		chunk, _ := ret[0].(string)
+		offset = toInt64(ret[1])
+		if overflow, _ := ret[2].(bool); overflow {
+			ch <- "*** LOG FILE OVERFLOW, SOME LOG DATA WAS LOST ***\n"
+		}
+		if chunk == "" {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+				continue
+			}
+		}
+		ch <- chunk
+	}
+}
+
+// resolveTailOffset translates the offset contract StdoutLog/StderrLog
+// share with the local openLogFile path - the sign is ignored and the
+// magnitude is how many bytes back from EOF to start, with 0 meaning "start
+// now" - into the absolute offset tailProcessStd{out,err}Log expects. It
+// asks supervisord for the log's current size via a zero-length read.
+func (p Process) resolveTailOffset(method, name string, offset int64) (int64, error) {
+	if offset < 0 {
+		offset = -offset
+	}
+	var ret []interface{}
+	if err := p.call(method, []interface{}{name, int64(0), int64(0)}, &ret); err != nil {
+		return 0, err
+	}
+	if len(ret) != 3 {
+		return 0, errors.New("unexpected tailProcessLog response shape")
+	}
+	start := toInt64(ret[1]) - offset
+	if start < 0 {
+		start = 0
+	}
+	return start, nil
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+func NewProcessControl(client *RpcClient, opts ...ProcessOption) *Process {
+	p := &Process{RpcClient: client}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
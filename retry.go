@@ -0,0 +1,149 @@
+package supervisord
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// retryPolicy configures how RpcClient.call retries a failed RPC. The zero
+// value never retries.
+type retryPolicy struct {
+	maxAttempts      int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	jitter           float64
+	reconnectOnError bool
+}
+
+// WithRetry enables retries for idempotent RPCs, trying up to maxAttempts
+// times before giving up. Non-idempotent calls (startProcess, stopProcess,
+// ...) are skipped unless the caller opts in per-call with WithForceRetry.
+func WithRetry(maxAttempts int) Option {
+	return func(c *RpcClient) {
+		c.retry.maxAttempts = maxAttempts
+	}
+}
+
+// WithBackoff sets the delay between retries: it starts at initial, doubles
+// on each attempt up to max, and is randomly adjusted by +/- jitter percent
+// (e.g. 0.2 for +/-20%) to avoid thundering-herd retries.
+func WithBackoff(initial, max time.Duration, jitter float64) Option {
+	return func(c *RpcClient) {
+		c.retry.initialBackoff = initial
+		c.retry.maxBackoff = max
+		c.retry.jitter = jitter
+	}
+}
+
+// WithReconnectOnError re-establishes the underlying transport (as if
+// NewRpcClient were called again) before each retry, for connection errors
+// that leave the xmlrpc.Client permanently unusable.
+func WithReconnectOnError() Option {
+	return func(c *RpcClient) {
+		c.retry.reconnectOnError = true
+	}
+}
+
+// CallOptions controls retry behavior for a single RPC.
+type CallOptions struct {
+	forceRetry bool
+}
+
+// CallOption mutates CallOptions; see WithForceRetry.
+type CallOption func(*CallOptions)
+
+// WithForceRetry allows a call to be retried even if it targets a
+// non-idempotent supervisord RPC such as startProcess or stopProcess. Only
+// use this when the caller can tolerate the action running more than once.
+func WithForceRetry() CallOption {
+	return func(o *CallOptions) {
+		o.forceRetry = true
+	}
+}
+
+// nonIdempotentMethods lists supervisord RPCs that mutate state and must not
+// be retried by default, since a retry after a dropped response could repeat
+// the action rather than just re-read it.
+var nonIdempotentMethods = map[string]bool{
+	"supervisor.startProcess":       true,
+	"supervisor.startAllProcesses":  true,
+	"supervisor.startProcessGroup":  true,
+	"supervisor.stopProcess":        true,
+	"supervisor.stopAllProcesses":   true,
+	"supervisor.stopProcessGroup":   true,
+	"supervisor.signalProcess":      true,
+	"supervisor.signalProcessGroup": true,
+	"supervisor.signalAllProcesses": true,
+	"supervisor.sendProcessStdin":   true,
+	"supervisor.addProcessGroup":    true,
+	"supervisor.removeProcessGroup": true,
+	"supervisor.restart":            true,
+	"supervisor.shutdown":           true,
+}
+
+// call wraps rpc.Call with the client's retry policy. Retries are attempted
+// only for methods considered idempotent, unless opts opts in with
+// WithForceRetry, and only for errors isRetryableError recognizes as
+// transport-level failures rather than RPC faults.
+func (r *RpcClient) call(method string, args, reply interface{}, opts ...CallOption) error {
+	if r.retry.maxAttempts < 2 {
+		return r.rpc.Call(method, args, reply)
+	}
+	var o CallOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if nonIdempotentMethods[method] && !o.forceRetry {
+		return r.rpc.Call(method, args, reply)
+	}
+	backoff := r.retry.initialBackoff
+	var err error
+	for attempt := 1; attempt <= r.retry.maxAttempts; attempt++ {
+		err = r.rpc.Call(method, args, reply)
+		if err == nil || !isRetryableError(err) {
+			return err
+		}
+		if attempt == r.retry.maxAttempts {
+			break
+		}
+		if r.retry.reconnectOnError {
+			_ = r.initRpcClient()
+		}
+		if backoff > 0 {
+			time.Sleep(withJitter(backoff, r.retry.jitter))
+			backoff *= 2
+			if r.retry.maxBackoff > 0 && backoff > r.retry.maxBackoff {
+				backoff = r.retry.maxBackoff
+			}
+		}
+	}
+	return err
+}
+
+// isRetryableError reports whether err looks like a transient transport
+// failure (dropped connection, reset, gateway error) rather than a
+// supervisord fault response, which should never be retried blindly.
+func isRetryableError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "502") || strings.Contains(msg, "503")
+}
+
+func withJitter(d time.Duration, pct float64) time.Duration {
+	if pct <= 0 {
+		return d
+	}
+	delta := float64(d) * pct * (rand.Float64()*2 - 1)
+	return d + time.Duration(delta)
+}
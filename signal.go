@@ -0,0 +1,84 @@
+package supervisord
+
+import (
+	"strconv"
+	"syscall"
+)
+
+// SignalProcess sends signal (e.g. "HUP", "TERM") to a single process.
+func (p Process) SignalProcess(name, signal string, opts ...CallOption) error {
+	return p.call("supervisor.signalProcess", []interface{}{name, signal}, nil, opts...)
+}
+
+// SignalProcessGroup sends signal to every process in group.
+func (p Process) SignalProcessGroup(group, signal string, opts ...CallOption) ([]StartStopRet, error) {
+	var ret []StartStopRet
+	return ret, p.call("supervisor.signalProcessGroup", []interface{}{group, signal}, &ret, opts...)
+}
+
+// SignalAllProcesses sends signal to every running process.
+func (p Process) SignalAllProcesses(signal string, opts ...CallOption) (StartStopAllRet, error) {
+	var ret StartStopAllRet
+	return ret, p.call("supervisor.signalAllProcesses", signal, &ret, opts...)
+}
+
+// StartProcessGroup starts every process in group.
+func (p Process) StartProcessGroup(group string, opts ...CallOption) (StartStopAllRet, error) {
+	var ret StartStopAllRet
+	return ret, p.call("supervisor.startProcessGroup", group, &ret, opts...)
+}
+
+// StopProcessGroup stops every process in group.
+func (p Process) StopProcessGroup(group string, opts ...CallOption) (StartStopAllRet, error) {
+	var ret StartStopAllRet
+	return ret, p.call("supervisor.stopProcessGroup", group, &ret, opts...)
+}
+
+// SendProcessStdin writes chars to a process's stdin. The process must be
+// running and its stdin must not have been closed.
+func (p Process) SendProcessStdin(name, chars string, opts ...CallOption) error {
+	return p.call("supervisor.sendProcessStdin", []interface{}{name, chars}, nil, opts...)
+}
+
+// SignalName converts sig to the string form supervisord's signal RPCs
+// expect (e.g. syscall.SIGHUP -> "HUP"), so callers aren't required to spell
+// signal names out by hand. Signals without a name listed here are returned
+// as their number (e.g. "7"), which supervisord also accepts.
+func SignalName(sig syscall.Signal) string {
+	switch sig {
+	case syscall.SIGHUP:
+		return "HUP"
+	case syscall.SIGINT:
+		return "INT"
+	case syscall.SIGQUIT:
+		return "QUIT"
+	case syscall.SIGILL:
+		return "ILL"
+	case syscall.SIGTRAP:
+		return "TRAP"
+	case syscall.SIGABRT:
+		return "ABRT"
+	case syscall.SIGKILL:
+		return "KILL"
+	case syscall.SIGUSR1:
+		return "USR1"
+	case syscall.SIGUSR2:
+		return "USR2"
+	case syscall.SIGSEGV:
+		return "SEGV"
+	case syscall.SIGPIPE:
+		return "PIPE"
+	case syscall.SIGALRM:
+		return "ALRM"
+	case syscall.SIGTERM:
+		return "TERM"
+	case syscall.SIGCHLD:
+		return "CHLD"
+	case syscall.SIGCONT:
+		return "CONT"
+	case syscall.SIGSTOP:
+		return "STOP"
+	default:
+		return strconv.Itoa(int(sig))
+	}
+}
@@ -0,0 +1,163 @@
+package supervisord
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Riete/supervisord/eventlistener"
+)
+
+// WatchInterval is how often Watch polls getAllProcessInfo when it falls
+// back to polling (see Watch). Callers may override it before calling Watch.
+var WatchInterval = 2 * time.Second
+
+// StateChange describes a single process transitioning from one supervisord
+// state to another, as observed by Watch.
+type StateChange struct {
+	Name  string
+	Group string
+	From  string
+	To    string
+	Pid   int64
+	Time  time.Time
+}
+
+// processStateEvents lists the PROCESS_STATE_* event names supervisord
+// emits, matching the Stopped/Starting/Running/... state constants.
+var processStateEvents = []string{
+	"PROCESS_STATE_STOPPED",
+	"PROCESS_STATE_STARTING",
+	"PROCESS_STATE_RUNNING",
+	"PROCESS_STATE_BACKOFF",
+	"PROCESS_STATE_STOPPING",
+	"PROCESS_STATE_EXITED",
+	"PROCESS_STATE_FATAL",
+	"PROCESS_STATE_UNKNOWN",
+}
+
+// Watch returns a channel that receives a StateChange every time one of the
+// named processes transitions between STOPPED/STARTING/RUNNING/BACKOFF/
+// STOPPING/EXITED/FATAL. With no names, every process is watched.
+//
+// When the Process was built with WithEventListener, Watch registers against
+// that listener's PROCESS_STATE_* events instead of polling, since they carry
+// transitions the moment supervisord emits them. Otherwise it falls back to
+// polling AllInfo on WatchInterval and diffing against the previous
+// snapshot. The returned channel is closed once ctx is done.
+func (p Process) Watch(ctx context.Context, names ...string) (<-chan StateChange, error) {
+	ch := make(chan StateChange, 1)
+	if p.eventListener != nil {
+		go p.watchEvents(ctx, names, ch)
+	} else {
+		go p.watchPoll(ctx, names, ch)
+	}
+	return ch, nil
+}
+
+func watchFilter(names []string) func(string) bool {
+	return func(name string) bool {
+		if len(names) == 0 {
+			return true
+		}
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (p Process) watchPoll(ctx context.Context, names []string, ch chan StateChange) {
+	defer close(ch)
+	watched := watchFilter(names)
+	prev := make(map[string]string)
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+	for {
+		infos, err := p.AllInfo()
+		if err == nil {
+			for _, info := range infos {
+				if !watched(info.Name) {
+					continue
+				}
+				from, seen := prev[info.Name]
+				prev[info.Name] = info.StateName
+				if seen && from != info.StateName {
+					send(ch, StateChange{
+						Name:  info.Name,
+						Group: info.Group,
+						From:  from,
+						To:    info.StateName,
+						Pid:   info.Pid,
+						Time:  time.Now(),
+					})
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchEvents registers against the attached EventListener's PROCESS_STATE_*
+// events and translates them into StateChange values. l.Listen is expected
+// to already be running elsewhere (typically because this program is itself
+// supervisord's `[eventlistener:x]` child); watchEvents only hooks Handle.
+func (p Process) watchEvents(ctx context.Context, names []string, ch chan StateChange) {
+	defer close(ch)
+	watched := watchFilter(names)
+	pending := make(chan StateChange, 16)
+	handler := func(_ context.Context, event eventlistener.Event) error {
+		pse, ok := event.(eventlistener.ProcessStateEvent)
+		if !ok || !watched(pse.ProcessName) {
+			return nil
+		}
+		select {
+		case pending <- StateChange{
+			Name:  pse.ProcessName,
+			Group: pse.GroupName,
+			From:  pse.FromState,
+			To:    strings.TrimPrefix(pse.EventName, "PROCESS_STATE_"),
+			Pid:   int64(pse.Pid),
+			Time:  time.Now(),
+		}:
+		default:
+		}
+		return nil
+	}
+	for _, name := range processStateEvents {
+		p.eventListener.Handle(name, handler)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sc := <-pending:
+			send(ch, sc)
+		}
+	}
+}
+
+// send delivers sc without blocking the producer: if the buffered channel is
+// full, the oldest pending change is dropped in favor of sc so a slow reader
+// still sees the most recent state.
+func send(ch chan StateChange, sc StateChange) {
+	select {
+	case ch <- sc:
+		return
+	default:
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- sc:
+	default:
+	}
+}